@@ -0,0 +1,50 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Authenticator adds authentication information to an outgoing request. It
+// is invoked by RequestBuilder.Build() when a RequestBuilder has one
+// configured via WithAuthenticator.
+type Authenticator interface {
+	// Authenticate adds authentication information (e.g. headers) to the
+	// given RequestBuilder.
+	Authenticate(*RequestBuilder) error
+
+	// Validate checks that the authenticator has been configured
+	// correctly and is ready to authenticate requests.
+	Validate() error
+}
+
+// NoAuthAuthenticator is an Authenticator that does nothing, for use with
+// services or test fixtures that don't require authentication.
+type NoAuthAuthenticator struct{}
+
+// NewNoAuthAuthenticator creates a new NoAuthAuthenticator.
+func NewNoAuthAuthenticator() *NoAuthAuthenticator {
+	return &NoAuthAuthenticator{}
+}
+
+// Authenticate does nothing; NoAuthAuthenticator adds no authentication
+// information to the request.
+func (*NoAuthAuthenticator) Authenticate(*RequestBuilder) error {
+	return nil
+}
+
+// Validate always succeeds for NoAuthAuthenticator.
+func (*NoAuthAuthenticator) Validate() error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestNoAuthAuthenticator(t *testing.T) {
+	authenticator := NewNoAuthAuthenticator()
+	assert.Nil(t, authenticator.Validate())
+
+	request := NewRequestBuilder("GET").ConstructHTTPURL("https://test.com", nil, nil).
+		WithAuthenticator(authenticator)
+	req, err := request.Build()
+	assert.Nil(t, err)
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestBearerTokenAuthenticator(t *testing.T) {
+	authenticator := NewBearerTokenAuthenticator("my-token")
+	assert.Nil(t, authenticator.Validate())
+
+	request := NewRequestBuilder("GET").ConstructHTTPURL("https://test.com", nil, nil).
+		WithAuthenticator(authenticator)
+	req, err := request.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer my-token", req.Header.Get("Authorization"))
+
+	assert.NotNil(t, NewBearerTokenAuthenticator("").Validate())
+}
+
+func TestBasicAuthenticator(t *testing.T) {
+	authenticator := NewBasicAuthenticator("user", "pass")
+	assert.Nil(t, authenticator.Validate())
+
+	request := NewRequestBuilder("GET").ConstructHTTPURL("https://test.com", nil, nil).
+		WithAuthenticator(authenticator)
+	req, err := request.Build()
+	assert.Nil(t, err)
+	username, password, ok := req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "pass", password)
+
+	assert.NotNil(t, NewBasicAuthenticator("", "pass").Validate())
+	assert.NotNil(t, NewBasicAuthenticator("user", "").Validate())
+}
+
+func TestIAMAuthenticatorValidate(t *testing.T) {
+	assert.NotNil(t, NewIAMAuthenticator("").Validate())
+	assert.Nil(t, NewIAMAuthenticator("apikey").Validate())
+}
+
+func TestIAMAuthenticatorFetchesAndCachesToken(t *testing.T) {
+	var tokenRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		assert.Nil(t, r.ParseForm())
+		assert.Equal(t, "urn:ibm:params:oauth:grant-type:apikey", r.FormValue("grant_type"))
+		assert.Equal(t, "my-apikey", r.FormValue("apikey"))
+
+		fmt.Fprintf(w, `{"access_token": "token-%d", "expiration": %d}`, tokenRequests, time.Now().Add(time.Hour).Unix())
+	}))
+	defer server.Close()
+
+	authenticator := NewIAMAuthenticator("my-apikey")
+	authenticator.URL = server.URL
+
+	request := NewRequestBuilder("GET").ConstructHTTPURL("https://test.com", nil, nil).
+		WithAuthenticator(authenticator)
+	req, err := request.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer token-1", req.Header.Get("Authorization"))
+
+	// A second request within the token's lifetime should reuse the
+	// cached token rather than calling the token endpoint again.
+	request2 := NewRequestBuilder("GET").ConstructHTTPURL("https://test.com", nil, nil).
+		WithAuthenticator(authenticator)
+	req2, err := request2.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer token-1", req2.Header.Get("Authorization"))
+	assert.Equal(t, 1, tokenRequests)
+}
+
+func TestIAMAuthenticatorRefreshesExpiringToken(t *testing.T) {
+	var tokenRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		// Report the token as already expired, so that every request
+		// falls within the refresh window regardless of how quickly
+		// the test executes.
+		fmt.Fprintf(w, `{"access_token": "token-%d", "expiration": %d}`, tokenRequests, time.Now().Unix())
+	}))
+	defer server.Close()
+
+	authenticator := NewIAMAuthenticator("my-apikey")
+	authenticator.URL = server.URL
+
+	_, err := NewRequestBuilder("GET").ConstructHTTPURL("https://test.com", nil, nil).
+		WithAuthenticator(authenticator).Build()
+	assert.Nil(t, err)
+
+	_, err = NewRequestBuilder("GET").ConstructHTTPURL("https://test.com", nil, nil).
+		WithAuthenticator(authenticator).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, tokenRequests)
+}
@@ -0,0 +1,55 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// BasicAuthenticator adds HTTP Basic authentication credentials to each
+// request's Authorization header.
+type BasicAuthenticator struct {
+	Username string
+	Password string
+}
+
+// NewBasicAuthenticator creates a new BasicAuthenticator with the given
+// username and password.
+func NewBasicAuthenticator(username string, password string) *BasicAuthenticator {
+	return &BasicAuthenticator{Username: username, Password: password}
+}
+
+// Authenticate adds an "Authorization: Basic <credentials>" header to the
+// request.
+func (authenticator *BasicAuthenticator) Authenticate(requestBuilder *RequestBuilder) error {
+	credentials := authenticator.Username + ":" + authenticator.Password
+	encoded := base64.StdEncoding.EncodeToString([]byte(credentials))
+	requestBuilder.AddHeader("Authorization", "Basic "+encoded)
+	return nil
+}
+
+// Validate ensures that both a username and a password have been configured.
+func (authenticator *BasicAuthenticator) Validate() error {
+	if authenticator.Username == "" {
+		return fmt.Errorf("username must not be empty")
+	}
+	if authenticator.Password == "" {
+		return fmt.Errorf("password must not be empty")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import "fmt"
+
+// BearerTokenAuthenticator adds a caller-supplied bearer token to each
+// request's Authorization header. Unlike IAMAuthenticator, it performs no
+// token refresh of its own; callers are responsible for keeping BearerToken
+// up to date.
+type BearerTokenAuthenticator struct {
+	BearerToken string
+}
+
+// NewBearerTokenAuthenticator creates a new BearerTokenAuthenticator with
+// the given token.
+func NewBearerTokenAuthenticator(bearerToken string) *BearerTokenAuthenticator {
+	return &BearerTokenAuthenticator{BearerToken: bearerToken}
+}
+
+// Authenticate adds an "Authorization: Bearer <token>" header to the request.
+func (authenticator *BearerTokenAuthenticator) Authenticate(requestBuilder *RequestBuilder) error {
+	requestBuilder.AddHeader("Authorization", "Bearer "+authenticator.BearerToken)
+	return nil
+}
+
+// Validate ensures that a bearer token has been configured.
+func (authenticator *BearerTokenAuthenticator) Validate() error {
+	if authenticator.BearerToken == "" {
+		return fmt.Errorf("bearer token must not be empty")
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Producer converts a Go value into the wire representation for a
+// particular MIME type, returning an io.Reader the caller can use as a
+// request body.
+type Producer func(value interface{}) (io.Reader, error)
+
+// Consumer converts wire bytes for a particular MIME type into target,
+// which is typically a pointer to the caller's destination value.
+type Consumer func(data []byte, target interface{}) error
+
+var contentHandlers = struct {
+	mutex     sync.RWMutex
+	producers map[string]Producer
+	consumers map[string]Consumer
+}{
+	producers: map[string]Producer{
+		"application/json":                  jsonProducer,
+		"application/xml":                   xmlProducer,
+		"application/x-www-form-urlencoded": formURLEncodedProducer,
+		"text/plain":                        textPlainProducer,
+		"application/octet-stream":          octetStreamProducer,
+	},
+	consumers: map[string]Consumer{
+		"application/json":                  jsonConsumer,
+		"application/xml":                   xmlConsumer,
+		"application/x-www-form-urlencoded": formURLEncodedConsumer,
+		"text/plain":                        textPlainConsumer,
+		"application/octet-stream":          octetStreamConsumer,
+	},
+}
+
+// RegisterProducer registers producer as the handler used by SetBodyContent
+// to serialize values for the given MIME type, overriding any existing
+// producer registered for it.
+func RegisterProducer(contentType string, producer Producer) {
+	contentHandlers.mutex.Lock()
+	defer contentHandlers.mutex.Unlock()
+	contentHandlers.producers[baseMIMEType(contentType)] = producer
+}
+
+// RegisterConsumer registers consumer as the handler used by
+// UnmarshalResponse to deserialize responses for the given MIME type,
+// overriding any existing consumer registered for it.
+func RegisterConsumer(contentType string, consumer Consumer) {
+	contentHandlers.mutex.Lock()
+	defer contentHandlers.mutex.Unlock()
+	contentHandlers.consumers[baseMIMEType(contentType)] = consumer
+}
+
+// producerFor returns the registered Producer for contentType, if any.
+func producerFor(contentType string) (Producer, bool) {
+	contentHandlers.mutex.RLock()
+	defer contentHandlers.mutex.RUnlock()
+	producer, ok := contentHandlers.producers[baseMIMEType(contentType)]
+	return producer, ok
+}
+
+// consumerFor returns the registered Consumer for contentType, if any.
+func consumerFor(contentType string) (Consumer, bool) {
+	contentHandlers.mutex.RLock()
+	defer contentHandlers.mutex.RUnlock()
+	consumer, ok := contentHandlers.consumers[baseMIMEType(contentType)]
+	return consumer, ok
+}
+
+// baseMIMEType strips any parameters (e.g. "; charset=utf-8") from a
+// Content-Type header value.
+func baseMIMEType(contentType string) string {
+	base, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	}
+	return base
+}
+
+func jsonProducer(value interface{}) (io.Reader, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(string(data)), nil
+}
+
+func jsonConsumer(data []byte, target interface{}) error {
+	return json.Unmarshal(data, target)
+}
+
+func xmlProducer(value interface{}) (io.Reader, error) {
+	data, err := xml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(string(data)), nil
+}
+
+func xmlConsumer(data []byte, target interface{}) error {
+	return xml.Unmarshal(data, target)
+}
+
+func formURLEncodedProducer(value interface{}) (io.Reader, error) {
+	values, ok := value.(url.Values)
+	if !ok {
+		return nil, fmt.Errorf("application/x-www-form-urlencoded producer requires a url.Values, got %T", value)
+	}
+	return strings.NewReader(values.Encode()), nil
+}
+
+func formURLEncodedConsumer(data []byte, target interface{}) error {
+	values, ok := target.(*url.Values)
+	if !ok {
+		return fmt.Errorf("application/x-www-form-urlencoded consumer requires a *url.Values target, got %T", target)
+	}
+	parsed, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	*values = parsed
+	return nil
+}
+
+func textPlainProducer(value interface{}) (io.Reader, error) {
+	switch v := value.(type) {
+	case string:
+		return strings.NewReader(v), nil
+	case *string:
+		return strings.NewReader(*v), nil
+	case fmt.Stringer:
+		return strings.NewReader(v.String()), nil
+	default:
+		return nil, fmt.Errorf("text/plain producer requires a string, got %T", value)
+	}
+}
+
+func textPlainConsumer(data []byte, target interface{}) error {
+	switch t := target.(type) {
+	case *string:
+		*t = string(data)
+		return nil
+	default:
+		return fmt.Errorf("text/plain consumer requires a *string target, got %T", target)
+	}
+}
+
+func octetStreamProducer(value interface{}) (io.Reader, error) {
+	switch v := value.(type) {
+	case io.Reader:
+		return v, nil
+	case []byte:
+		return strings.NewReader(string(v)), nil
+	default:
+		return nil, fmt.Errorf("application/octet-stream producer requires an io.Reader or []byte, got %T", value)
+	}
+}
+
+func octetStreamConsumer(data []byte, target interface{}) error {
+	switch t := target.(type) {
+	case *[]byte:
+		*t = data
+		return nil
+	default:
+		return fmt.Errorf("application/octet-stream consumer requires a *[]byte target, got %T", target)
+	}
+}
+
+// UnmarshalResponse reads resp.Body and deserializes it into target using
+// the Consumer registered for contentType. If contentType is empty, the
+// response's Content-Type header is used instead. If no consumer is
+// registered for the resulting content type, an error is returned.
+func UnmarshalResponse(resp *http.Response, contentType string, target interface{}) error {
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if contentType == "" {
+		contentType = resp.Header.Get("Content-Type")
+	}
+
+	consumer, ok := consumerFor(contentType)
+	if !ok {
+		return fmt.Errorf("no consumer registered for content type %q", contentType)
+	}
+	return consumer(data, target)
+}
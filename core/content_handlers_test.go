@@ -0,0 +1,113 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestSetBodyContentUsesRegisteredTextPlainProducer(t *testing.T) {
+	request := setup()
+	request.SetBodyContent("text/plain", nil, nil, "hello registry")
+	assert.Equal(t, "hello registry", readStream(request.Body))
+}
+
+func TestUnmarshalResponseJSON(t *testing.T) {
+	resp := &http.Response{
+		Body:   ioutil.NopCloser(strings.NewReader(`{"name":"wonder woman"}`)),
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+	}
+
+	var target TestStructure
+	err := UnmarshalResponse(resp, "", &target)
+	assert.Nil(t, err)
+	assert.Equal(t, "wonder woman", target.Name)
+}
+
+func TestUnmarshalResponseTextPlain(t *testing.T) {
+	resp := &http.Response{
+		Body: ioutil.NopCloser(strings.NewReader("plain text body")),
+	}
+
+	var target string
+	err := UnmarshalResponse(resp, "text/plain", &target)
+	assert.Nil(t, err)
+	assert.Equal(t, "plain text body", target)
+}
+
+func TestUnmarshalResponseUnknownContentType(t *testing.T) {
+	resp := &http.Response{
+		Body: ioutil.NopCloser(strings.NewReader("whatever")),
+	}
+
+	err := UnmarshalResponse(resp, "application/x-unknown", &struct{}{})
+	assert.NotNil(t, err)
+}
+
+func TestSetBodyContentFormURLEncodedRoundTrip(t *testing.T) {
+	request := setup()
+	request.SetBodyContent(FORM_URL_ENCODED_HEADER, nil, nil, url.Values{"name": []string{"wonder woman"}})
+	assert.Equal(t, "name=wonder+woman", readStream(request.Body))
+
+	resp := &http.Response{
+		Body: ioutil.NopCloser(strings.NewReader("name=wonder+woman")),
+	}
+	var target url.Values
+	err := UnmarshalResponse(resp, FORM_URL_ENCODED_HEADER, &target)
+	assert.Nil(t, err)
+	assert.Equal(t, "wonder woman", target.Get("name"))
+}
+
+func TestRegisterCustomContentType(t *testing.T) {
+	const cborContentType = "application/cbor"
+
+	RegisterProducer(cborContentType, func(value interface{}) (io.Reader, error) {
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", value)
+		}
+		return bytes.NewReader([]byte("cbor:" + str)), nil
+	})
+	RegisterConsumer(cborContentType, func(data []byte, target interface{}) error {
+		str, ok := target.(*string)
+		if !ok {
+			return fmt.Errorf("expected *string target, got %T", target)
+		}
+		*str = strings.TrimPrefix(string(data), "cbor:")
+		return nil
+	})
+
+	request := setup()
+	request.SetBodyContent(cborContentType, nil, nil, "payload")
+	assert.Equal(t, "cbor:payload", readStream(request.Body))
+
+	resp := &http.Response{
+		Body: ioutil.NopCloser(strings.NewReader("cbor:payload")),
+	}
+	var target string
+	assert.Nil(t, UnmarshalResponse(resp, cborContentType, &target))
+	assert.Equal(t, "payload", target)
+}
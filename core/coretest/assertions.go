@@ -0,0 +1,109 @@
+package coretest
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// AssertFormField asserts that field was submitted (as a multipart field or
+// an application/x-www-form-urlencoded value) with the given value.
+func (r *Recorded) AssertFormField(t *testing.T, field string, want string) {
+	t.Helper()
+
+	var got string
+	var found bool
+	if r.Multipart != nil {
+		if part, ok := r.Multipart[field]; ok {
+			got, found = string(part.Content), true
+		}
+	} else if r.Form != nil {
+		if _, ok := r.Form[field]; ok {
+			got, found = r.Form.Get(field), true
+		}
+	}
+
+	if !found {
+		t.Errorf("form field %q was not present", field)
+		return
+	}
+	if got != want {
+		t.Errorf("form field %q = %q, want %q", field, got, want)
+	}
+}
+
+// AssertMultipartFile asserts that a multipart file part was submitted
+// under field with the given filename and content type. Pass an empty
+// contentType to skip that check.
+func (r *Recorded) AssertMultipartFile(t *testing.T, field string, filename string, contentType string) {
+	t.Helper()
+
+	part, ok := r.Multipart[field]
+	if !ok {
+		t.Errorf("multipart field %q was not present", field)
+		return
+	}
+	if part.Filename != filename {
+		t.Errorf("multipart field %q filename = %q, want %q", field, part.Filename, filename)
+	}
+	if contentType != "" && part.ContentType != contentType {
+		t.Errorf("multipart field %q content type = %q, want %q", field, part.ContentType, contentType)
+	}
+}
+
+// AssertJSONPath asserts that the value at path within the recorded JSON
+// body equals want. path is a dot-separated path rooted at "$", e.g.
+// "$.name" or "$.address.city".
+func (r *Recorded) AssertJSONPath(t *testing.T, path string, want interface{}) {
+	t.Helper()
+
+	got, err := jsonPathValue(r.JSON, path)
+	if err != nil {
+		t.Errorf("JSON path %q: %v", path, err)
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON path %q = %#v, want %#v", path, got, want)
+	}
+}
+
+// jsonPathValue looks up a dot-separated path (rooted at "$") within a
+// decoded JSON object tree.
+func jsonPathValue(root map[string]interface{}, path string) (interface{}, error) {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return root, nil
+	}
+
+	var current interface{} = root
+	for _, segment := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot descend into %q: %v is not an object", segment, current)
+		}
+		value, ok := object[segment]
+		if !ok {
+			return nil, fmt.Errorf("field %q not found", segment)
+		}
+		current = value
+	}
+	return current, nil
+}
@@ -0,0 +1,70 @@
+package coretest
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"net/http"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+
+	"github.com/isgasho/go-sdk-core/core"
+)
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestHarnessRunCapturesJSONBody(t *testing.T) {
+	rb := core.NewRequestBuilder("POST").
+		ConstructHTTPURL("https://test.com", nil, nil).
+		AddQuery("Version", "2018-22-09")
+	rb.SetBodyContentJSON(map[string]interface{}{"name": "wonder woman"})
+
+	err := New().Handler(http.HandlerFunc(echoHandler)).Run(rb, func(rec *Recorded) {
+		assert.Equal(t, "POST", rec.Method)
+		assert.Equal(t, "2018-22-09", rec.Query.Get("Version"))
+		rec.AssertJSONPath(t, "$.name", "wonder woman")
+		assert.Equal(t, http.StatusOK, rec.Response.Code)
+	})
+	assert.Nil(t, err)
+}
+
+func TestHarnessRunCapturesMultipartForm(t *testing.T) {
+	rb := core.NewRequestBuilder("POST").
+		ConstructHTTPURL("https://test.com", nil, nil).
+		AddFormData("name", "", "text/plain", "wonder woman").
+		AddFormData("avatar", "avatar.png", "image/png", "fake-png-bytes")
+
+	err := New().Handler(http.HandlerFunc(echoHandler)).Run(rb, func(rec *Recorded) {
+		rec.AssertFormField(t, "name", "wonder woman")
+		rec.AssertMultipartFile(t, "avatar", "avatar.png", "image/png")
+	})
+	assert.Nil(t, err)
+}
+
+func TestHarnessRunCapturesURLEncodedForm(t *testing.T) {
+	rb := core.NewRequestBuilder("POST").
+		ConstructHTTPURL("https://test.com", nil, nil).
+		AddHeader("Content-Type", core.FORM_URL_ENCODED_HEADER).
+		AddFormData("grant_type", "", "", "client_credentials")
+
+	err := New().Handler(http.HandlerFunc(echoHandler)).Run(rb, func(rec *Recorded) {
+		rec.AssertFormField(t, "grant_type", "client_credentials")
+	})
+	assert.Nil(t, err)
+}
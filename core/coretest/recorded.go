@@ -0,0 +1,140 @@
+package coretest
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+// MultipartPart is a single part captured from a multipart/form-data
+// request body.
+type MultipartPart struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// Recorded describes everything a Harness observed about a single request
+// and its response.
+type Recorded struct {
+	Method string
+	URL    *url.URL
+	Query  url.Values
+	Header http.Header
+
+	// JSON holds the request body decoded as JSON, when the request's
+	// Content-Type was application/json.
+	JSON map[string]interface{}
+
+	// Form holds the request body decoded as form values, when the
+	// request's Content-Type was application/x-www-form-urlencoded.
+	Form url.Values
+
+	// Multipart holds the decoded parts of the request body, keyed by
+	// field name, when the request's Content-Type was multipart/form-data.
+	Multipart map[string]MultipartPart
+
+	// Response is the recorder that captured the handler's response.
+	Response *httptest.ResponseRecorder
+}
+
+// newRecorded builds a Recorded from req and its already-drained body
+// bytes.
+func newRecorded(req *http.Request, bodyBytes []byte) (*Recorded, error) {
+	recorded := &Recorded{
+		Method: req.Method,
+		URL:    req.URL,
+		Query:  req.URL.Query(),
+		Header: req.Header,
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	if contentType == "" {
+		return recorded, nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	switch {
+	case mediaType == "application/json":
+		if len(bodyBytes) == 0 {
+			break
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(bodyBytes, &body); err != nil {
+			return nil, err
+		}
+		recorded.JSON = body
+
+	case strings.HasPrefix(mediaType, "multipart/"):
+		parts, err := decodeMultipart(bodyBytes, params["boundary"])
+		if err != nil {
+			return nil, err
+		}
+		recorded.Multipart = parts
+
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		recorded.Form = values
+	}
+
+	return recorded, nil
+}
+
+// decodeMultipart parses a multipart/form-data body into its named parts.
+func decodeMultipart(bodyBytes []byte, boundary string) (map[string]MultipartPart, error) {
+	reader := multipart.NewReader(bytes.NewReader(bodyBytes), boundary)
+	parts := make(map[string]MultipartPart)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		parts[part.FormName()] = MultipartPart{
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Content:     content,
+		}
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,174 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// FieldError is a single error entry from an IBM Cloud error envelope of
+// the form {"errors":[{"code","message","more_info","target"}]}.
+type FieldError struct {
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message,omitempty"`
+	MoreInfo string `json:"more_info,omitempty"`
+	Target   string `json:"target,omitempty"`
+}
+
+// DetailedError describes a non-2xx HTTP response, with as much detail as
+// could be recovered from the response body and headers.
+type DetailedError struct {
+	StatusCode    int
+	Message       string
+	ErrorCode     string
+	TransactionID string
+	Headers       http.Header
+	RawBody       []byte
+	Errors        []FieldError
+}
+
+// Error implements the error interface.
+func (e *DetailedError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("[%d] %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+}
+
+// Unwrap supports errors.Is/errors.As chains built on top of DetailedError.
+// DetailedError itself has no further-wrapped cause, so this always
+// returns nil.
+func (e *DetailedError) Unwrap() error {
+	return nil
+}
+
+// ProcessResponse reads and returns the raw body of resp. If resp's status
+// code indicates an error (>= 300), it also returns a *DetailedError
+// describing it, decoded from whichever error envelope the body matches:
+// an IBM-style {"errors":[...]}/{"error":"..."}/{"message":"..."} body, an
+// RFC 7807 application/problem+json body, or (failing those) the raw body
+// text.
+func ProcessResponse(resp *http.Response) ([]byte, error) {
+	rawBody, err := readAndCloseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 300 {
+		return rawBody, nil
+	}
+
+	return rawBody, newDetailedError(resp, rawBody)
+}
+
+func readAndCloseBody(resp *http.Response) ([]byte, error) {
+	if resp.Body == nil {
+		return nil, nil
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+func newDetailedError(resp *http.Response, rawBody []byte) *DetailedError {
+	detailedError := &DetailedError{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		RawBody:    rawBody,
+		TransactionID: firstNonEmpty(
+			resp.Header.Get("X-Global-Transaction-Id"),
+			resp.Header.Get("X-DP-Watson-Tran-ID"),
+		),
+	}
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "application/problem+json") {
+		parseProblemDetails(rawBody, detailedError)
+	} else {
+		parseIBMErrorEnvelope(rawBody, detailedError)
+	}
+
+	if detailedError.Message == "" {
+		detailedError.Message = fallbackMessage(rawBody, resp.StatusCode)
+	}
+
+	return detailedError
+}
+
+// parseIBMErrorEnvelope recognizes the {"errors":[...]}/{"error":"..."}/
+// {"message":"..."} error envelopes commonly returned by IBM Cloud services.
+func parseIBMErrorEnvelope(rawBody []byte, detailedError *DetailedError) {
+	var envelope struct {
+		Errors  []FieldError `json:"errors"`
+		Error   string       `json:"error"`
+		Message string       `json:"message"`
+	}
+	if err := json.Unmarshal(rawBody, &envelope); err != nil {
+		return
+	}
+
+	switch {
+	case len(envelope.Errors) > 0:
+		detailedError.Errors = envelope.Errors
+		detailedError.Message = envelope.Errors[0].Message
+		detailedError.ErrorCode = envelope.Errors[0].Code
+	case envelope.Message != "":
+		detailedError.Message = envelope.Message
+	case envelope.Error != "":
+		detailedError.Message = envelope.Error
+	}
+}
+
+// parseProblemDetails recognizes RFC 7807 application/problem+json bodies.
+func parseProblemDetails(rawBody []byte, detailedError *DetailedError) {
+	var problem struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	}
+	if err := json.Unmarshal(rawBody, &problem); err != nil {
+		return
+	}
+
+	detailedError.ErrorCode = problem.Type
+	if problem.Detail != "" {
+		detailedError.Message = problem.Detail
+	} else {
+		detailedError.Message = problem.Title
+	}
+}
+
+// fallbackMessage is used when a non-2xx response body didn't match any
+// recognized error envelope.
+func fallbackMessage(rawBody []byte, statusCode int) string {
+	if text := strings.TrimSpace(string(rawBody)); text != "" {
+		return text
+	}
+	return fmt.Sprintf("unexpected status code %d", statusCode)
+}
+
+// firstNonEmpty returns the first non-empty string in values, or "".
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
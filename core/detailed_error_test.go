@@ -0,0 +1,133 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func newErrorResponse(statusCode int, contentType string, body string, headers map[string]string) *http.Response {
+	resp := &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+	if contentType != "" {
+		resp.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range headers {
+		resp.Header.Set(name, value)
+	}
+	return resp
+}
+
+func TestProcessResponseSuccess(t *testing.T) {
+	resp := newErrorResponse(http.StatusOK, "application/json", `{"name":"wonder woman"}`, nil)
+	rawBody, err := ProcessResponse(resp)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"wonder woman"}`, string(rawBody))
+}
+
+func TestProcessResponseErrorsEnvelope(t *testing.T) {
+	body := `{"errors":[{"code":"not_found","message":"workspace not found","target":"workspace_id"}]}`
+	resp := newErrorResponse(http.StatusNotFound, "application/json", body, map[string]string{
+		"X-Global-Transaction-Id": "txn-123",
+	})
+
+	_, err := ProcessResponse(resp)
+	assert.NotNil(t, err)
+
+	var detailedError *DetailedError
+	assert.True(t, errors.As(err, &detailedError))
+	assert.Equal(t, http.StatusNotFound, detailedError.StatusCode)
+	assert.Equal(t, "workspace not found", detailedError.Message)
+	assert.Equal(t, "not_found", detailedError.ErrorCode)
+	assert.Equal(t, "txn-123", detailedError.TransactionID)
+	assert.Equal(t, 1, len(detailedError.Errors))
+}
+
+func TestProcessResponseErrorField(t *testing.T) {
+	resp := newErrorResponse(http.StatusBadRequest, "application/json", `{"error":"invalid apikey"}`, nil)
+	_, err := ProcessResponse(resp)
+	var detailedError *DetailedError
+	assert.True(t, errors.As(err, &detailedError))
+	assert.Equal(t, "invalid apikey", detailedError.Message)
+}
+
+func TestProcessResponseMessageField(t *testing.T) {
+	resp := newErrorResponse(http.StatusInternalServerError, "application/json", `{"message":"internal error"}`, nil)
+	_, err := ProcessResponse(resp)
+	var detailedError *DetailedError
+	assert.True(t, errors.As(err, &detailedError))
+	assert.Equal(t, "internal error", detailedError.Message)
+}
+
+func TestProcessResponseProblemDetails(t *testing.T) {
+	body := `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","detail":"Your current balance is 30, but that costs 50.","status":403}`
+	resp := newErrorResponse(http.StatusForbidden, "application/problem+json", body, nil)
+
+	_, err := ProcessResponse(resp)
+	var detailedError *DetailedError
+	assert.True(t, errors.As(err, &detailedError))
+	assert.Equal(t, "Your current balance is 30, but that costs 50.", detailedError.Message)
+	assert.Equal(t, "https://example.com/probs/out-of-credit", detailedError.ErrorCode)
+}
+
+func TestProcessResponsePlainTextFallback(t *testing.T) {
+	resp := newErrorResponse(http.StatusBadGateway, "text/plain", "upstream is down", nil)
+	_, err := ProcessResponse(resp)
+	var detailedError *DetailedError
+	assert.True(t, errors.As(err, &detailedError))
+	assert.Equal(t, "upstream is down", detailedError.Message)
+}
+
+func TestRequestBuilderInvoke(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"wonder woman"}`))
+	}))
+	defer server.Close()
+
+	request := NewRequestBuilder(http.MethodGet).ConstructHTTPURL(server.URL, nil, nil)
+	resp, err := request.Invoke(http.DefaultClient)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.GetStatusCode())
+	assert.Equal(t, `{"name":"wonder woman"}`, string(resp.GetResult()))
+}
+
+func TestRequestBuilderInvokeReturnsDetailedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"code":"not_found","message":"not found"}]}`))
+	}))
+	defer server.Close()
+
+	request := NewRequestBuilder(http.MethodGet).ConstructHTTPURL(server.URL, nil, nil)
+	_, err := request.Invoke(http.DefaultClient)
+	var detailedError *DetailedError
+	assert.True(t, errors.As(err, &detailedError))
+	assert.Equal(t, http.StatusNotFound, detailedError.StatusCode)
+	assert.Equal(t, "not found", detailedError.Message)
+}
@@ -0,0 +1,70 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import "net/http"
+
+// DetailedResponse wraps a successful (2xx) HTTP response, giving callers
+// access to the status code, headers, and raw body without needing to
+// manage the underlying *http.Response themselves.
+type DetailedResponse struct {
+	StatusCode int
+	Headers    http.Header
+	RawResult  []byte
+}
+
+// GetStatusCode returns the response's HTTP status code.
+func (response *DetailedResponse) GetStatusCode() int {
+	return response.StatusCode
+}
+
+// GetHeaders returns the response's HTTP headers.
+func (response *DetailedResponse) GetHeaders() http.Header {
+	return response.Headers
+}
+
+// GetResult returns the response's raw body.
+func (response *DetailedResponse) GetResult() []byte {
+	return response.RawResult
+}
+
+// Invoke builds the request and executes it with client, returning a
+// *DetailedResponse on success. If the response's status code indicates an
+// error, the returned error is a *DetailedError describing it (see
+// ProcessResponse).
+func (requestBuilder *RequestBuilder) Invoke(client *http.Client) (*DetailedResponse, error) {
+	req, err := requestBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rawResult, err := ProcessResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DetailedResponse{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		RawResult:  rawResult,
+	}, nil
+}
@@ -0,0 +1,201 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// GRPCFieldMapper populates a gRPC request message from the path
+// parameters, query parameters, and body of a REST-style call, following
+// the google.api.http reverse-transcoding convention. pathParameters is
+// the slice of path parameter values passed to RequestBuilder.ConstructHTTPURL,
+// in order.
+type GRPCFieldMapper func(pathParameters []string, query map[string][]string, body interface{}, request proto.Message) error
+
+// GRPCDescriptor maps a REST-style {method, pathSegments} call onto a gRPC
+// service method.
+type GRPCDescriptor struct {
+	// Service is the fully-qualified gRPC service name, e.g.
+	// "watson.assistant.v1.MessageService".
+	Service string
+
+	// Method is the gRPC method name, e.g. "Message".
+	Method string
+
+	// NewRequest returns a new, empty request message for the call. It
+	// must be a proto.Message, since it is marshaled by gRPC's default
+	// codec.
+	NewRequest func() proto.Message
+
+	// NewResponse returns a new, empty response message for the call. It
+	// must be a proto.Message, since it is unmarshaled by gRPC's default
+	// codec.
+	NewResponse func() proto.Message
+
+	// MapFields populates a request returned by NewRequest from the
+	// path, query, and body parameters of the originating RequestBuilder.
+	MapFields GRPCFieldMapper
+}
+
+// fullMethod returns the gRPC method string ("/service/method") for desc.
+func (desc GRPCDescriptor) fullMethod() string {
+	return fmt.Sprintf("/%s/%s", desc.Service, desc.Method)
+}
+
+// GRPCInvocation is a gRPC call ready to be dispatched by a GRPCClient: its
+// Request has already been populated from the originating RequestBuilder's
+// path, query, and body parameters.
+type GRPCInvocation struct {
+	FullMethod  string
+	Request     proto.Message
+	NewResponse func() proto.Message
+
+	// Metadata carries the authentication (and any other) headers added
+	// by the originating RequestBuilder's Authenticator, for GRPCClient.
+	// Invoke to attach to the outgoing call.
+	Metadata metadata.MD
+}
+
+// BuildGRPC materializes the RequestBuilder's method, URL, query, and body
+// against desc, instead of building an *http.Request, so the call can be
+// dispatched over a gRPC transport by a GRPCClient. This lets a call site
+// written against the REST-style RequestBuilder API transparently target a
+// gRPC-based backend, including its configured Authenticator: if one is
+// set, it is validated and invoked exactly as Build() would invoke it, and
+// the headers it adds are carried on the invocation as gRPC metadata.
+func (requestBuilder *RequestBuilder) BuildGRPC(desc GRPCDescriptor) (*GRPCInvocation, error) {
+	if desc.NewRequest == nil || desc.MapFields == nil {
+		return nil, fmt.Errorf("GRPCDescriptor must set NewRequest and MapFields")
+	}
+
+	if requestBuilder.Authenticator != nil {
+		if err := requestBuilder.Authenticator.Validate(); err != nil {
+			return nil, err
+		}
+		if err := requestBuilder.Authenticator.Authenticate(requestBuilder); err != nil {
+			return nil, err
+		}
+	}
+
+	request := desc.NewRequest()
+	if err := desc.MapFields(requestBuilder.pathParameters, requestBuilder.Query, decodeGRPCBody(requestBuilder.Body), request); err != nil {
+		return nil, err
+	}
+
+	return &GRPCInvocation{
+		FullMethod:  desc.fullMethod(),
+		Request:     request,
+		NewResponse: desc.NewResponse,
+		Metadata:    headerToMetadata(requestBuilder.Header),
+	}, nil
+}
+
+// headerToMetadata converts an http.Header into gRPC metadata, lowercasing
+// keys to match gRPC's metadata convention.
+func headerToMetadata(header map[string][]string) metadata.MD {
+	md := make(metadata.MD, len(header))
+	for key, values := range header {
+		md[strings.ToLower(key)] = values
+	}
+	return md
+}
+
+// decodeGRPCBody best-effort decodes a request body for use by a
+// GRPCFieldMapper: JSON object/array bodies are returned decoded, and
+// anything else is returned as a string.
+func decodeGRPCBody(body io.Reader) interface{} {
+	if body == nil {
+		return nil
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err == nil {
+		return value
+	}
+	return string(data)
+}
+
+// GRPCClient dispatches REST-style calls to a gRPC backend using a
+// registered table of GRPCDescriptors. The Authenticator configured on a
+// RequestBuilder is applied by BuildGRPC and its headers are sent as gRPC
+// metadata. Calls are made once: Invoke does not retry, and
+// BuildWithRetry's retry policy applies only to HTTP requests.
+type GRPCClient struct {
+	conn *grpc.ClientConn
+
+	mutex       sync.RWMutex
+	descriptors map[string]GRPCDescriptor
+}
+
+// NewGRPCClient creates a GRPCClient that dispatches calls over conn.
+func NewGRPCClient(conn *grpc.ClientConn) *GRPCClient {
+	return &GRPCClient{conn: conn, descriptors: make(map[string]GRPCDescriptor)}
+}
+
+// Register associates a REST-style {method, pathSegments} tuple with desc,
+// so that a matching RequestBuilder can be dispatched over gRPC via
+// Invoke.
+func (client *GRPCClient) Register(restMethod string, pathSegments []string, desc GRPCDescriptor) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	client.descriptors[grpcDescriptorKey(restMethod, pathSegments)] = desc
+}
+
+// Descriptor returns the GRPCDescriptor registered for the given REST-style
+// method and path segments, if any.
+func (client *GRPCClient) Descriptor(restMethod string, pathSegments []string) (GRPCDescriptor, bool) {
+	client.mutex.RLock()
+	defer client.mutex.RUnlock()
+	desc, ok := client.descriptors[grpcDescriptorKey(restMethod, pathSegments)]
+	return desc, ok
+}
+
+// grpcDescriptorKey builds the lookup key used by the descriptor registry.
+func grpcDescriptorKey(restMethod string, pathSegments []string) string {
+	return restMethod + " " + strings.Join(pathSegments, "/")
+}
+
+// Invoke dispatches invocation over the client's gRPC connection and
+// returns the populated response message. If invocation carries metadata
+// (added by the originating RequestBuilder's Authenticator via BuildGRPC),
+// it is attached to the outgoing call.
+func (client *GRPCClient) Invoke(ctx context.Context, invocation *GRPCInvocation) (proto.Message, error) {
+	if len(invocation.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, invocation.Metadata)
+	}
+
+	response := invocation.NewResponse()
+	if err := client.conn.Invoke(ctx, invocation.FullMethod, invocation.Request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,231 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// messageDescriptor describes watson.assistant.v1.MessageService/Message
+// using google.protobuf.Struct as a stand-in for generated request/response
+// proto types, so the descriptor and its field mapping can be exercised
+// without requiring protoc-generated code.
+func messageDescriptor() GRPCDescriptor {
+	return GRPCDescriptor{
+		Service: "watson.assistant.v1.MessageService",
+		Method:  "Message",
+		NewRequest: func() proto.Message {
+			return &structpb.Struct{Fields: map[string]*structpb.Value{}}
+		},
+		NewResponse: func() proto.Message {
+			return &structpb.Struct{Fields: map[string]*structpb.Value{}}
+		},
+		MapFields: func(pathParameters []string, query map[string][]string, body interface{}, request proto.Message) error {
+			req := request.(*structpb.Struct)
+			if len(pathParameters) < 1 {
+				return fmt.Errorf("expected a workspaceId path parameter, got %v", pathParameters)
+			}
+			req.Fields["workspaceId"] = structpb.NewStringValue(pathParameters[0])
+
+			if versions, ok := query["version"]; ok && len(versions) > 0 {
+				req.Fields["version"] = structpb.NewStringValue(versions[0])
+			}
+
+			if bodyMap, ok := body.(map[string]interface{}); ok {
+				if text, ok := bodyMap["text"].(string); ok {
+					req.Fields["text"] = structpb.NewStringValue(text)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func TestBuildGRPCMapsPathQueryAndBodyOntoRequest(t *testing.T) {
+	requestBuilder := NewRequestBuilder("POST").
+		ConstructHTTPURL("https://gateway.watsonplatform.net/assistant/api", []string{"v1/workspaces", "message"}, []string{"abc123"}).
+		AddQuery("version", "2018-22-09")
+	_, err := requestBuilder.SetBodyContentJSON(map[string]interface{}{"text": "hello"})
+	assert.Nil(t, err)
+
+	invocation, err := requestBuilder.BuildGRPC(messageDescriptor())
+	assert.Nil(t, err)
+	assert.Equal(t, "/watson.assistant.v1.MessageService/Message", invocation.FullMethod)
+
+	req, ok := invocation.Request.(*structpb.Struct)
+	assert.True(t, ok)
+	assert.Equal(t, "abc123", req.Fields["workspaceId"].GetStringValue())
+	assert.Equal(t, "2018-22-09", req.Fields["version"].GetStringValue())
+	assert.Equal(t, "hello", req.Fields["text"].GetStringValue())
+
+	resp, ok := invocation.NewResponse().(*structpb.Struct)
+	assert.True(t, ok)
+	assert.NotNil(t, resp)
+}
+
+func TestBuildGRPCAppliesAuthenticatorToMetadata(t *testing.T) {
+	requestBuilder := NewRequestBuilder("POST").
+		ConstructHTTPURL("https://gateway.watsonplatform.net/assistant/api", []string{"v1/workspaces", "message"}, []string{"abc123"}).
+		WithAuthenticator(NewBearerTokenAuthenticator("secret-token")).
+		AddQuery("version", "2018-22-09")
+	_, err := requestBuilder.SetBodyContentJSON(map[string]interface{}{"text": "hello"})
+	assert.Nil(t, err)
+
+	invocation, err := requestBuilder.BuildGRPC(messageDescriptor())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Bearer secret-token"}, invocation.Metadata.Get("authorization"))
+}
+
+func TestBuildGRPCReturnsAuthenticatorValidateError(t *testing.T) {
+	requestBuilder := NewRequestBuilder("POST").
+		ConstructHTTPURL("https://gateway.watsonplatform.net/assistant/api", []string{"v1/workspaces", "message"}, []string{"abc123"}).
+		WithAuthenticator(NewBearerTokenAuthenticator(""))
+
+	_, err := requestBuilder.BuildGRPC(messageDescriptor())
+	assert.NotNil(t, err)
+}
+
+func TestGRPCClientDescriptorRegistry(t *testing.T) {
+	client := NewGRPCClient(nil)
+	desc := messageDescriptor()
+	pathSegments := []string{"v1/workspaces", "message"}
+
+	_, ok := client.Descriptor("POST", pathSegments)
+	assert.False(t, ok)
+
+	client.Register("POST", pathSegments, desc)
+	registered, ok := client.Descriptor("POST", pathSegments)
+	assert.True(t, ok)
+	assert.Equal(t, desc.Service, registered.Service)
+}
+
+// startMessageServer spins up an in-process gRPC server implementing
+// watson.assistant.v1.MessageService/Message over a bufconn listener, so
+// GRPCClient.Invoke can be exercised against a real gRPC wire call. The
+// server echoes the request's "text" field into the response's "output"
+// field and copies its "workspaceId" field and the incoming "authorization"
+// metadata into the response, so a bufconn round-trip can confirm the path
+// parameter mapped by MapFields actually reached the server as part of the
+// marshaled request.
+func startMessageServer(t *testing.T) (*grpc.ClientConn, func()) {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "watson.assistant.v1.MessageService",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Message",
+				Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+					in := &structpb.Struct{Fields: map[string]*structpb.Value{}}
+					if err := dec(in); err != nil {
+						return nil, err
+					}
+					var authorization string
+					if md, ok := metadata.FromIncomingContext(ctx); ok {
+						if values := md.Get("authorization"); len(values) > 0 {
+							authorization = values[0]
+						}
+					}
+					out := &structpb.Struct{Fields: map[string]*structpb.Value{
+						"output":        structpb.NewStringValue("echo:" + in.Fields["text"].GetStringValue()),
+						"workspaceId":   in.Fields["workspaceId"],
+						"authorization": structpb.NewStringValue(authorization),
+					}}
+					return out, nil
+				},
+			},
+		},
+	}, nil)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+
+	return conn, func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestGRPCClientInvokeRoundTripsOverBufconn(t *testing.T) {
+	conn, cleanup := startMessageServer(t)
+	defer cleanup()
+
+	requestBuilder := NewRequestBuilder("POST").
+		ConstructHTTPURL("https://gateway.watsonplatform.net/assistant/api", []string{"v1/workspaces", "message"}, []string{"abc123"}).
+		AddQuery("version", "2018-22-09")
+	_, err := requestBuilder.SetBodyContentJSON(map[string]interface{}{"text": "hello"})
+	assert.Nil(t, err)
+
+	invocation, err := requestBuilder.BuildGRPC(messageDescriptor())
+	assert.Nil(t, err)
+
+	client := NewGRPCClient(conn)
+	response, err := client.Invoke(context.Background(), invocation)
+	assert.Nil(t, err)
+
+	resp, ok := response.(*structpb.Struct)
+	assert.True(t, ok)
+	assert.Equal(t, "echo:hello", resp.Fields["output"].GetStringValue())
+	assert.Equal(t, "abc123", resp.Fields["workspaceId"].GetStringValue())
+	assert.Equal(t, "", resp.Fields["authorization"].GetStringValue())
+}
+
+func TestGRPCClientInvokeCarriesAuthenticatorMetadataOverBufconn(t *testing.T) {
+	conn, cleanup := startMessageServer(t)
+	defer cleanup()
+
+	requestBuilder := NewRequestBuilder("POST").
+		ConstructHTTPURL("https://gateway.watsonplatform.net/assistant/api", []string{"v1/workspaces", "message"}, []string{"abc123"}).
+		WithAuthenticator(NewBearerTokenAuthenticator("secret-token")).
+		AddQuery("version", "2018-22-09")
+	_, err := requestBuilder.SetBodyContentJSON(map[string]interface{}{"text": "hello"})
+	assert.Nil(t, err)
+
+	invocation, err := requestBuilder.BuildGRPC(messageDescriptor())
+	assert.Nil(t, err)
+
+	client := NewGRPCClient(conn)
+	response, err := client.Invoke(context.Background(), invocation)
+	assert.Nil(t, err)
+
+	resp, ok := response.(*structpb.Struct)
+	assert.True(t, ok)
+	assert.Equal(t, "Bearer secret-token", resp.Fields["authorization"].GetStringValue())
+}
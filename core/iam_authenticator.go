@@ -0,0 +1,160 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultIAMTokenURL is used when an IAMAuthenticator does not specify its
+// own token exchange URL.
+const defaultIAMTokenURL = "https://iam.cloud.ibm.com/identity/token"
+
+// iamRefreshWindowFactor controls when a cached IAM token is proactively
+// refreshed: a refresh is triggered once less than this fraction of the
+// token's total lifetime remains.
+const iamRefreshWindowFactor = 0.2
+
+// IAMAuthenticator exchanges an IBM Cloud API key for a bearer token via the
+// IAM token endpoint, and adds that token to each request's Authorization
+// header. The token is cached and transparently refreshed.
+type IAMAuthenticator struct {
+	ApiKey string
+
+	// URL is the IAM token endpoint. If empty, defaultIAMTokenURL is used.
+	URL string
+
+	// Client is used to make the token exchange request. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+
+	mutex     sync.Mutex
+	token     string
+	issuedAt  time.Time
+	expiresAt time.Time
+}
+
+// iamTokenResponse is the subset of the IAM token endpoint's response body
+// that this authenticator cares about.
+type iamTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	Expiration  int64  `json:"expiration"`
+}
+
+// NewIAMAuthenticator creates a new IAMAuthenticator for the given API key,
+// using the default IAM token URL.
+func NewIAMAuthenticator(apiKey string) *IAMAuthenticator {
+	return &IAMAuthenticator{ApiKey: apiKey}
+}
+
+// Validate ensures that an API key has been configured.
+func (authenticator *IAMAuthenticator) Validate() error {
+	if authenticator.ApiKey == "" {
+		return fmt.Errorf("IAM API key must not be empty")
+	}
+	return nil
+}
+
+// Authenticate adds an "Authorization: Bearer <token>" header to the
+// request, fetching or refreshing the cached IAM token as needed.
+func (authenticator *IAMAuthenticator) Authenticate(requestBuilder *RequestBuilder) error {
+	token, err := authenticator.getToken()
+	if err != nil {
+		return err
+	}
+	requestBuilder.AddHeader("Authorization", "Bearer "+token)
+	return nil
+}
+
+// getToken returns a cached IAM token, requesting a new one if none is
+// cached or the cached one is due for refresh. It is safe for concurrent
+// use.
+func (authenticator *IAMAuthenticator) getToken() (string, error) {
+	authenticator.mutex.Lock()
+	defer authenticator.mutex.Unlock()
+
+	if authenticator.token != "" && !authenticator.needsRefresh() {
+		return authenticator.token, nil
+	}
+	return authenticator.requestToken()
+}
+
+// needsRefresh reports whether the cached token has less than
+// iamRefreshWindowFactor of its total lifetime remaining.
+func (authenticator *IAMAuthenticator) needsRefresh() bool {
+	lifetime := authenticator.expiresAt.Sub(authenticator.issuedAt)
+	remaining := time.Until(authenticator.expiresAt)
+	return remaining < time.Duration(iamRefreshWindowFactor*float64(lifetime))
+}
+
+// requestToken exchanges the configured API key for a fresh IAM token and
+// caches it.
+func (authenticator *IAMAuthenticator) requestToken() (string, error) {
+	tokenURL := authenticator.URL
+	if tokenURL == "" {
+		tokenURL = defaultIAMTokenURL
+	}
+
+	builder := NewRequestBuilder(http.MethodPost).
+		ConstructHTTPURL(tokenURL, nil, nil).
+		AddHeader("Content-Type", FORM_URL_ENCODED_HEADER).
+		AddHeader("Accept", "application/json").
+		AddFormData("grant_type", "", "", "urn:ibm:params:oauth:grant-type:apikey").
+		AddFormData("apikey", "", "", authenticator.ApiKey)
+
+	req, err := builder.Build()
+	if err != nil {
+		return "", err
+	}
+
+	client := authenticator.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	issuedAt := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("error retrieving IAM token: status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResponse iamTokenResponse
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return "", err
+	}
+
+	authenticator.token = tokenResponse.AccessToken
+	authenticator.issuedAt = issuedAt
+	authenticator.expiresAt = time.Unix(tokenResponse.Expiration, 0)
+
+	return authenticator.token, nil
+}
@@ -0,0 +1,80 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestBuildWithMultipartFormSetsContentLengthForSeekableParts(t *testing.T) {
+	tempFile, err := ioutil.TempFile("", "request-builder-multipart")
+	assert.Nil(t, err)
+	defer os.Remove(tempFile.Name())
+
+	contents := make([]byte, 1<<20) // 1 MiB, large enough to exercise streaming
+	_, err = tempFile.Write(contents)
+	assert.Nil(t, err)
+	_, err = tempFile.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+	defer tempFile.Close()
+
+	var reports []int64
+	request := NewRequestBuilder("POST").
+		ConstructHTTPURL("test.com", nil, nil).
+		AddFormData("file", "payload.bin", "application/octet-stream", tempFile).
+		WithProgress(func(sent, total int64) {
+			reports = append(reports, sent)
+			assert.True(t, total > 0)
+		})
+
+	req, err := request.Build()
+	assert.Nil(t, err)
+	assert.True(t, req.ContentLength > int64(len(contents)))
+
+	body, err := ioutil.ReadAll(req.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, int(req.ContentLength), len(body))
+
+	assert.NotEmpty(t, reports)
+	for i := 1; i < len(reports); i++ {
+		assert.True(t, reports[i] >= reports[i-1], "progress must be monotonically non-decreasing")
+	}
+	assert.Equal(t, int64(len(body)), reports[len(reports)-1])
+}
+
+func TestBuildWithMultipartFormWithoutKnownSizeOmitsContentLength(t *testing.T) {
+	// bytes.Buffer is an io.Reader but not an io.Seeker, so its size can't
+	// be determined without fully reading it.
+	request := NewRequestBuilder("POST").
+		ConstructHTTPURL("test.com", nil, nil).
+		AddFormData("hello", "", "text/plain", bytes.NewBufferString("hello GO SDK"))
+
+	req, err := request.Build()
+	assert.Nil(t, err)
+	assert.NotNil(t, req.Body)
+	assert.Equal(t, int64(0), req.ContentLength)
+
+	body, err := ioutil.ReadAll(req.Body)
+	assert.Nil(t, err)
+	assert.True(t, len(body) > 0)
+}
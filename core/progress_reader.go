@@ -0,0 +1,55 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import "io"
+
+// ProgressCallback is invoked as a ProgressReader is read, reporting the
+// number of bytes sent so far and, when known, the total number of bytes
+// that will be sent. total is 0 when the size of the underlying content
+// could not be determined in advance.
+type ProgressCallback func(sent, total int64)
+
+// ProgressReader wraps an io.Reader and reports the number of bytes read
+// through a ProgressCallback as they are consumed, e.g. while streaming a
+// multipart request body.
+type ProgressReader struct {
+	reader   io.Reader
+	total    int64
+	sent     int64
+	callback ProgressCallback
+}
+
+// NewProgressReader wraps reader so that each Read reports cumulative
+// progress to callback. total may be 0 if the size of reader's content is
+// unknown.
+func NewProgressReader(reader io.Reader, total int64, callback ProgressCallback) *ProgressReader {
+	return &ProgressReader{reader: reader, total: total, callback: callback}
+}
+
+// Read implements io.Reader, delegating to the wrapped reader and reporting
+// progress on every successful read.
+func (r *ProgressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		if r.callback != nil {
+			r.callback(r.sent, r.total)
+		}
+	}
+	return n, err
+}
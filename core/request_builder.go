@@ -0,0 +1,505 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// FORM_URL_ENCODED_HEADER is the Content-Type value used for form data that
+// should be sent as "application/x-www-form-urlencoded" rather than as a
+// multipart body.
+const FORM_URL_ENCODED_HEADER = "application/x-www-form-urlencoded"
+
+// formDataPart holds a single named part that was added to a RequestBuilder
+// via AddFormData, pending serialization when the request is built.
+type formDataPart struct {
+	fieldName   string
+	fileName    string
+	contentType string
+	contents    interface{}
+}
+
+// RequestBuilder facilitates the construction of http.Request objects
+// for an SDK that communicates with a REST API. Callers populate it using
+// the fluent methods below and call Build() to obtain the final
+// *http.Request.
+type RequestBuilder struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   io.Reader
+	Query  map[string][]string
+
+	// Authenticator, when set, is invoked by Build() to add
+	// authentication-related headers to the request before it is
+	// returned to the caller.
+	Authenticator Authenticator
+
+	formData []formDataPart
+
+	// progressCallback, when set via WithProgress, receives streaming
+	// progress updates while a multipart body built by this
+	// RequestBuilder is read.
+	progressCallback ProgressCallback
+
+	// contentLength is the computed size of a streamed multipart body,
+	// when it could be determined in advance.
+	contentLength int64
+
+	// pathParameters holds the path parameter values last passed to
+	// ConstructHTTPURL, so that a gRPC field mapper built via BuildGRPC
+	// can recover them without re-parsing the constructed URL.
+	pathParameters []string
+}
+
+// NewRequestBuilder initializes a RequestBuilder for the given HTTP method.
+func NewRequestBuilder(method string) *RequestBuilder {
+	return &RequestBuilder{
+		Method: method,
+		Header: make(http.Header),
+		Query:  make(map[string][]string),
+	}
+}
+
+// WithAuthenticator sets the Authenticator that Build() will invoke before
+// returning the constructed request.
+func (requestBuilder *RequestBuilder) WithAuthenticator(authenticator Authenticator) *RequestBuilder {
+	requestBuilder.Authenticator = authenticator
+	return requestBuilder
+}
+
+// ConstructHTTPURL constructs the full URL for the request by joining the
+// endpoint with the given path segments, interleaving the corresponding
+// path parameter (if any) after each segment.
+func (requestBuilder *RequestBuilder) ConstructHTTPURL(endPoint string, pathSegments []string, pathParameters []string) *RequestBuilder {
+	if endPoint == "" {
+		return requestBuilder
+	}
+
+	urlString := strings.TrimSuffix(endPoint, "/")
+	for i, pathSegment := range pathSegments {
+		if pathSegment != "" {
+			urlString += "/" + pathSegment
+		}
+		if pathParameters != nil && i < len(pathParameters) && pathParameters[i] != "" {
+			urlString += "/" + pathParameters[i]
+		}
+	}
+
+	requestBuilder.URL, _ = url.Parse(urlString)
+	requestBuilder.pathParameters = pathParameters
+	return requestBuilder
+}
+
+// AddQuery adds a query parameter to the request.
+func (requestBuilder *RequestBuilder) AddQuery(name string, value string) *RequestBuilder {
+	requestBuilder.Query[name] = append(requestBuilder.Query[name], value)
+	return requestBuilder
+}
+
+// AddHeader sets a header on the request.
+func (requestBuilder *RequestBuilder) AddHeader(name string, value string) *RequestBuilder {
+	requestBuilder.Header.Set(name, value)
+	return requestBuilder
+}
+
+// WithProgress registers a callback that is invoked with cumulative
+// bytes-sent progress as a multipart body built by this RequestBuilder is
+// read by the HTTP client. The total reported to callback is 0 if the size
+// of the body could not be determined in advance.
+func (requestBuilder *RequestBuilder) WithProgress(callback ProgressCallback) *RequestBuilder {
+	requestBuilder.progressCallback = callback
+	return requestBuilder
+}
+
+// AddFormData adds a named part to the request's form data. When the
+// request's Content-Type is FORM_URL_ENCODED_HEADER the parts are encoded as
+// "application/x-www-form-urlencoded"; otherwise they are written out as a
+// streamed multipart/form-data body. The size of string contents is known,
+// and the size of an *os.File or any other io.Seeker is computed via Seek.
+// When every part's size is known, Build() sets the request's
+// Content-Length; otherwise the body is sent using chunked transfer
+// encoding.
+func (requestBuilder *RequestBuilder) AddFormData(fieldName string, fileName string, contentType string, contents interface{}) *RequestBuilder {
+	requestBuilder.formData = append(requestBuilder.formData, formDataPart{
+		fieldName:   fieldName,
+		fileName:    fileName,
+		contentType: contentType,
+		contents:    contents,
+	})
+	return requestBuilder
+}
+
+// SetBodyContentJSON marshals bodyContent as JSON and uses it as the
+// request body, setting the Content-Type header to "application/json" if
+// no Content-Type header has been set yet.
+func (requestBuilder *RequestBuilder) SetBodyContentJSON(bodyContent interface{}) (*RequestBuilder, error) {
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(bodyContent); err != nil {
+		return requestBuilder, err
+	}
+	requestBuilder.Body = buf
+
+	if requestBuilder.Header.Get("Content-Type") == "" {
+		requestBuilder.Header.Set("Content-Type", "application/json")
+	}
+	return requestBuilder, nil
+}
+
+// SetBodyContentString uses the given string as the raw request body.
+func (requestBuilder *RequestBuilder) SetBodyContentString(body string) (*RequestBuilder, error) {
+	requestBuilder.Body = strings.NewReader(body)
+	return requestBuilder, nil
+}
+
+// SetBodyContent sets the request body from one of jsonContent,
+// jsonPatchContent, or nonJSONContent, in that order of precedence.
+// jsonContent and jsonPatchContent are marshalled as JSON. nonJSONContent
+// is serialized by the Producer registered for contentType, if there is
+// one; otherwise it is used as-is if it is a string, *string, io.Reader,
+// io.ReadCloser, or *io.ReadCloser, and any other type results in an error.
+func (requestBuilder *RequestBuilder) SetBodyContent(contentType string, jsonContent interface{}, jsonPatchContent interface{}, nonJSONContent interface{}) (*RequestBuilder, error) {
+	if !isNil(jsonContent) {
+		return requestBuilder.SetBodyContentJSON(jsonContent)
+	}
+
+	if !isNil(jsonPatchContent) {
+		if _, err := requestBuilder.SetBodyContentJSON(jsonPatchContent); err != nil {
+			return requestBuilder, err
+		}
+		requestBuilder.Header.Set("Content-Type", "application/json-patch+json")
+		return requestBuilder, nil
+	}
+
+	if !isNil(nonJSONContent) {
+		return requestBuilder.setBodyContentNonJSON(nonJSONContent, contentType)
+	}
+
+	return requestBuilder, nil
+}
+
+func (requestBuilder *RequestBuilder) setBodyContentNonJSON(nonJSONContent interface{}, contentType string) (*RequestBuilder, error) {
+	if contentType != "" {
+		requestBuilder.Header.Set("Content-Type", contentType)
+
+		// Prefer a registered Producer for the content type over the
+		// built-in stream/string handling below, so callers can plug
+		// in support for content types this package doesn't know
+		// about natively.
+		if producer, ok := producerFor(contentType); ok {
+			reader, err := producer(nonJSONContent)
+			if err != nil {
+				return requestBuilder, err
+			}
+			requestBuilder.Body = reader
+			return requestBuilder, nil
+		}
+	}
+
+	switch v := nonJSONContent.(type) {
+	case string:
+		requestBuilder.Body = strings.NewReader(v)
+	case *string:
+		requestBuilder.Body = strings.NewReader(*v)
+	case io.ReadCloser:
+		requestBuilder.Body = v
+	case *io.ReadCloser:
+		requestBuilder.Body = *v
+	case io.Reader:
+		requestBuilder.Body = v
+	default:
+		return requestBuilder, fmt.Errorf("Invalid type for non-JSON body content: %T", nonJSONContent)
+	}
+
+	return requestBuilder, nil
+}
+
+// contentsToReader converts the contents of a form data part into an
+// io.Reader, marshalling non-stream, non-string values as JSON.
+func contentsToReader(contents interface{}) (io.Reader, error) {
+	switch v := contents.(type) {
+	case string:
+		return strings.NewReader(v), nil
+	case *string:
+		return strings.NewReader(*v), nil
+	case io.ReadCloser:
+		return v, nil
+	case *io.ReadCloser:
+		return *v, nil
+	case io.Reader:
+		return v, nil
+	default:
+		bodyBytes, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(bodyBytes), nil
+	}
+}
+
+// contentsToString converts the contents of a form data part into a string,
+// for use when the part is encoded as "application/x-www-form-urlencoded".
+func contentsToString(contents interface{}) (string, error) {
+	switch v := contents.(type) {
+	case string:
+		return v, nil
+	case *string:
+		return *v, nil
+	default:
+		reader, err := contentsToReader(contents)
+		if err != nil {
+			return "", err
+		}
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(reader); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
+
+// createFormURLEncoded encodes the request's form data as
+// "application/x-www-form-urlencoded" and installs it as the request body.
+func (requestBuilder *RequestBuilder) createFormURLEncoded() error {
+	values := url.Values{}
+	for _, part := range requestBuilder.formData {
+		strValue, err := contentsToString(part.contents)
+		if err != nil {
+			return err
+		}
+		values.Add(part.fieldName, strValue)
+	}
+	requestBuilder.Body = strings.NewReader(values.Encode())
+	return nil
+}
+
+// createMultipartForm streams the request's form data out as a
+// multipart/form-data body via an io.Pipe, so that large form parts (e.g.
+// file uploads) don't need to be buffered in memory before the request is
+// sent. When the total size of the parts can be determined in advance, it
+// is recorded so Build() can set the request's Content-Length.
+func (requestBuilder *RequestBuilder) createMultipartForm() error {
+	contentLength, sizeKnown := requestBuilder.multipartContentLength()
+
+	pipeReader, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
+
+	go func() {
+		var err error
+		for _, part := range requestBuilder.formData {
+			if err = requestBuilder.writeMultipartPart(writer, part); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pipeWriter.CloseWithError(err)
+	}()
+
+	requestBuilder.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var body io.Reader = pipeReader
+	if requestBuilder.progressCallback != nil {
+		body = NewProgressReader(pipeReader, contentLength, requestBuilder.progressCallback)
+	}
+	requestBuilder.Body = body
+
+	if sizeKnown {
+		requestBuilder.contentLength = contentLength
+	}
+	return nil
+}
+
+// writeMultipartPart writes a single form data part's header and content to
+// writer.
+func (requestBuilder *RequestBuilder) writeMultipartPart(writer *multipart.Writer, part formDataPart) error {
+	reader, err := contentsToReader(part.contents)
+	if err != nil {
+		return err
+	}
+
+	var partWriter io.Writer
+	if part.fileName == "" {
+		partWriter, err = writer.CreateFormField(part.fieldName)
+	} else {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition",
+			fmt.Sprintf(`form-data; name="%s"; filename="%s"`, part.fieldName, part.fileName))
+		if part.contentType != "" {
+			header.Set("Content-Type", part.contentType)
+		}
+		partWriter, err = writer.CreatePart(header)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(partWriter, reader)
+	return err
+}
+
+// multipartContentLength computes the total size of the multipart body that
+// createMultipartForm would produce, returning ok=false if any part's
+// content size cannot be determined without fully reading it.
+func (requestBuilder *RequestBuilder) multipartContentLength() (int64, bool) {
+	buf := new(bytes.Buffer)
+	writer := multipart.NewWriter(buf)
+
+	var contentSize int64
+	for _, part := range requestBuilder.formData {
+		size, ok := formPartContentSize(part.contents)
+		if !ok {
+			return 0, false
+		}
+		contentSize += size
+
+		var err error
+		if part.fileName == "" {
+			_, err = writer.CreateFormField(part.fieldName)
+		} else {
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition",
+				fmt.Sprintf(`form-data; name="%s"; filename="%s"`, part.fieldName, part.fileName))
+			if part.contentType != "" {
+				header.Set("Content-Type", part.contentType)
+			}
+			_, err = writer.CreatePart(header)
+		}
+		if err != nil {
+			return 0, false
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return 0, false
+	}
+
+	// buf now holds only the boundary markers and part headers that
+	// multipart.Writer generated, since the part content itself was
+	// never written to it.
+	return int64(buf.Len()) + contentSize, true
+}
+
+// formPartContentSize returns the size in bytes of a form data part's
+// contents, if it can be determined without reading the whole value.
+func formPartContentSize(contents interface{}) (int64, bool) {
+	switch v := contents.(type) {
+	case string:
+		return int64(len(v)), true
+	case *string:
+		return int64(len(*v)), true
+	case *io.ReadCloser:
+		return formPartContentSize(*v)
+	case io.Seeker:
+		return seekerSize(v)
+	default:
+		return 0, false
+	}
+}
+
+// seekerSize returns the number of bytes remaining to be read from s,
+// leaving its position unchanged.
+func seekerSize(s io.Seeker) (int64, bool) {
+	current, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false
+	}
+	if _, err := s.Seek(current, io.SeekStart); err != nil {
+		return 0, false
+	}
+	return end - current, true
+}
+
+// Build assembles the configured method, URL, query, headers, and body into
+// an *http.Request. If an Authenticator is configured, it is validated and
+// applied to the RequestBuilder before the request is constructed.
+func (requestBuilder *RequestBuilder) Build() (*http.Request, error) {
+	if requestBuilder.Authenticator != nil {
+		if err := requestBuilder.Authenticator.Validate(); err != nil {
+			return nil, err
+		}
+		if err := requestBuilder.Authenticator.Authenticate(requestBuilder); err != nil {
+			return nil, err
+		}
+	}
+
+	if requestBuilder.URL == nil {
+		return nil, fmt.Errorf("the request URL was never set")
+	}
+
+	if len(requestBuilder.Query) > 0 {
+		query := requestBuilder.URL.Query()
+		for name, values := range requestBuilder.Query {
+			for _, value := range values {
+				query.Add(name, value)
+			}
+		}
+		requestBuilder.URL.RawQuery = query.Encode()
+	}
+
+	if len(requestBuilder.formData) > 0 {
+		var err error
+		if requestBuilder.Header.Get("Content-Type") == FORM_URL_ENCODED_HEADER {
+			err = requestBuilder.createFormURLEncoded()
+		} else {
+			err = requestBuilder.createMultipartForm()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(requestBuilder.Method, requestBuilder.URL.String(), requestBuilder.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = requestBuilder.Header
+	if requestBuilder.contentLength > 0 {
+		req.ContentLength = requestBuilder.contentLength
+	}
+
+	return req, nil
+}
+
+// isNil reports whether i is nil, including the case where i wraps a typed
+// nil pointer, channel, func, interface, map, or slice.
+func isNil(i interface{}) bool {
+	if i == nil {
+		return true
+	}
+	v := reflect.ValueOf(i)
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,270 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Policy configures RequestBuilder.BuildWithRetry's retry behavior.
+type Policy struct {
+	// InitialInterval is the backoff delay before the first retry.
+	InitialInterval time.Duration
+
+	// Multiplier scales the backoff delay after each retry.
+	Multiplier float64
+
+	// MaxInterval caps the backoff delay between retries.
+	MaxInterval time.Duration
+
+	// MaxElapsedTime bounds the total time spent retrying. Zero means no
+	// limit.
+	MaxElapsedTime time.Duration
+
+	// MaxRetries bounds the number of retry attempts. Zero means no
+	// limit beyond MaxElapsedTime.
+	MaxRetries int
+
+	// RandomizationFactor adds jitter to each computed backoff delay: the
+	// actual delay is chosen uniformly from
+	// [delay*(1-RandomizationFactor), delay*(1+RandomizationFactor)].
+	RandomizationFactor float64
+
+	// RetryableStatusCodes overrides the default set of HTTP status codes
+	// that are considered retryable (429 and 5xx). When non-empty, only
+	// these status codes are retried.
+	RetryableStatusCodes map[int]bool
+
+	// MaxBufferedBodyBytes caps how many bytes of a request body
+	// BuildWithRetry will buffer in memory in order to replay it on a
+	// retry, for bodies that aren't already replayable via
+	// http.Request.GetBody (e.g. a streamed multipart upload). The body
+	// is captured as it is sent on the first attempt, up to this limit,
+	// rather than read in full before sending; the captured copy is used
+	// only if a retry turns out to be needed. If the body turns out to be
+	// larger than this limit, the capture is discarded and the body can't
+	// be replayed and the request is not retried. Zero means
+	// DefaultMaxBufferedBodyBytes; a negative value disables the limit.
+	MaxBufferedBodyBytes int64
+}
+
+// DefaultMaxBufferedBodyBytes is used in place of
+// Policy.MaxBufferedBodyBytes when it is zero.
+const DefaultMaxBufferedBodyBytes int64 = 2 * 1024 * 1024 // 2MiB
+
+// DefaultRetryPolicy returns a Policy with reasonable default backoff
+// parameters.
+func DefaultRetryPolicy() Policy {
+	return Policy{
+		InitialInterval:     500 * time.Millisecond,
+		Multiplier:          2.0,
+		MaxInterval:         30 * time.Second,
+		MaxElapsedTime:      2 * time.Minute,
+		RandomizationFactor: 0.5,
+	}
+}
+
+// maxBufferedBodyBytes resolves the effective buffering cap for policy,
+// substituting DefaultMaxBufferedBodyBytes for the zero value.
+func (policy Policy) maxBufferedBodyBytes() int64 {
+	if policy.MaxBufferedBodyBytes == 0 {
+		return DefaultMaxBufferedBodyBytes
+	}
+	return policy.MaxBufferedBodyBytes
+}
+
+// isRetryableStatus reports whether resp's status code should be retried
+// under policy.
+func (policy Policy) isRetryableStatus(statusCode int) bool {
+	if len(policy.RetryableStatusCodes) > 0 {
+		return policy.RetryableStatusCodes[statusCode]
+	}
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// isIdempotent reports whether method is safe to automatically retry.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoffDelay computes the jittered backoff delay for the given retry
+// attempt (0-indexed).
+func (policy Policy) backoffDelay(attempt int) time.Duration {
+	delay := float64(policy.InitialInterval) * pow(policy.Multiplier, attempt)
+	if max := float64(policy.MaxInterval); policy.MaxInterval > 0 && delay > max {
+		delay = max
+	}
+
+	if policy.RandomizationFactor > 0 {
+		delta := policy.RandomizationFactor * delay
+		delay = delay - delta + rand.Float64()*2*delta
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return time.Duration(delay)
+}
+
+func pow(base float64, exp int) float64 {
+	result := 1.0
+	for i := 0; i < exp; i++ {
+		result *= base
+	}
+	return result
+}
+
+// retryAfterDelay parses a Retry-After header (either a number of seconds
+// or an HTTP-date) and returns how long to wait before the next attempt.
+func retryAfterDelay(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
+
+// boundedBodyCapture wraps a request body that isn't already replayable via
+// http.Request.GetBody, recording the bytes read through it (up to max) so
+// BuildWithRetry can replay them on a retry without having buffered the
+// body eagerly or unboundedly up front. If more than max bytes are read,
+// capturing is abandoned and exceeded is set, signaling that this body
+// can't be replayed.
+type boundedBodyCapture struct {
+	io.Reader
+	max      int64
+	buf      bytes.Buffer
+	exceeded bool
+}
+
+func newBoundedBodyCapture(r io.Reader, max int64) *boundedBodyCapture {
+	return &boundedBodyCapture{Reader: r, max: max}
+}
+
+func (c *boundedBodyCapture) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	if n > 0 && !c.exceeded {
+		if c.max >= 0 && int64(c.buf.Len()+n) > c.max {
+			c.exceeded = true
+			c.buf.Reset()
+		} else {
+			c.buf.Write(p[:n])
+		}
+	}
+	return n, err
+}
+
+// getBody returns a fresh, replayable copy of the request body for a retry
+// attempt, using req.GetBody if it was set and otherwise the copy captured
+// by capture on the first attempt. It returns nil, nil if the body can't be
+// replayed (no capture was taken, or it exceeded its configured limit).
+func getBody(req *http.Request, capture *boundedBodyCapture) (io.ReadCloser, error) {
+	if req.GetBody != nil {
+		return req.GetBody()
+	}
+	if capture == nil || capture.exceeded {
+		return nil, nil
+	}
+	return ioutil.NopCloser(bytes.NewReader(capture.buf.Bytes())), nil
+}
+
+// BuildWithRetry builds the request and executes it with client, retrying
+// idempotent requests that fail with a retryable status code according to
+// policy. If the request's body is not already replayable via
+// http.Request.GetBody, BuildWithRetry captures a copy of it as it is sent
+// -- up to policy's MaxBufferedBodyBytes -- so it can replay a fresh copy
+// if a retry turns out to be needed; a body succeeding on its first
+// attempt is never buffered up front, and a body larger than the limit
+// simply isn't retried.
+func (requestBuilder *RequestBuilder) BuildWithRetry(ctx context.Context, client *http.Client, policy Policy) (*http.Response, error) {
+	req, err := requestBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	var capture *boundedBodyCapture
+	if req.GetBody == nil && req.Body != nil {
+		capture = newBoundedBodyCapture(req.Body, policy.maxBufferedBodyBytes())
+		req.Body = ioutil.NopCloser(capture)
+	}
+
+	start := time.Now()
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			body, bodyErr := getBody(req, capture)
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req.Body = body
+		}
+
+		resp, err = client.Do(req)
+		if err != nil {
+			return resp, err
+		}
+
+		if !isIdempotent(req.Method) || !policy.isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+
+		if policy.MaxRetries > 0 && attempt >= policy.MaxRetries {
+			return resp, nil
+		}
+
+		if capture != nil && capture.exceeded {
+			// The body couldn't be captured within the configured limit,
+			// so it can't be safely replayed for a retry.
+			return resp, nil
+		}
+
+		delay := policy.backoffDelay(attempt)
+		if retryAfter, ok := retryAfterDelay(resp.Header.Get("Retry-After")); ok {
+			delay = retryAfter
+		}
+
+		if policy.MaxElapsedTime > 0 && time.Since(start)+delay > policy.MaxElapsedTime {
+			return resp, nil
+		}
+
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package core
+
+/**
+ * Copyright 2019 IBM All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+// opaqueReader wraps an io.Reader, hiding any io.Seeker/Len it might
+// otherwise implement, so http.NewRequest can't set req.GetBody from it --
+// the same shape as a streamed (e.g. multipart) upload body.
+type opaqueReader struct {
+	io.Reader
+}
+
+func fastTestPolicy() Policy {
+	return Policy{
+		InitialInterval:     time.Millisecond,
+		Multiplier:          2.0,
+		MaxInterval:         10 * time.Millisecond,
+		MaxElapsedTime:      time.Second,
+		RandomizationFactor: 0,
+	}
+}
+
+func TestBuildWithRetrySucceedsAfterTransientFailures(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request := NewRequestBuilder(http.MethodGet).ConstructHTTPURL(server.URL, nil, nil)
+	resp, err := request.BuildWithRetry(context.Background(), http.DefaultClient, fastTestPolicy())
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestBuildWithRetryDoesNotRetryNonIdempotentMethods(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	request := NewRequestBuilder(http.MethodPost).ConstructHTTPURL(server.URL, nil, nil)
+	resp, err := request.BuildWithRetry(context.Background(), http.DefaultClient, fastTestPolicy())
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestBuildWithRetryRespectsMaxRetries(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := fastTestPolicy()
+	policy.MaxRetries = 2
+
+	request := NewRequestBuilder(http.MethodGet).ConstructHTTPURL(server.URL, nil, nil)
+	resp, err := request.BuildWithRetry(context.Background(), http.DefaultClient, policy)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 3, attempts) // initial attempt + 2 retries
+}
+
+func TestBuildWithRetryHonorsRetryAfterSeconds(t *testing.T) {
+	var attempts int
+	var firstAttempt time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			firstAttempt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		assert.True(t, time.Since(firstAttempt) >= time.Second)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	policy := fastTestPolicy()
+	policy.MaxElapsedTime = 5 * time.Second
+
+	request := NewRequestBuilder(http.MethodGet).ConstructHTTPURL(server.URL, nil, nil)
+	resp, err := request.BuildWithRetry(context.Background(), http.DefaultClient, policy)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestBuildWithRetryReplaysCapturedNonSeekableBody(t *testing.T) {
+	var attempts int
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if attempts < 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request := NewRequestBuilder(http.MethodGet).ConstructHTTPURL(server.URL, nil, nil)
+	request.Body = opaqueReader{strings.NewReader("retry me")}
+
+	resp, err := request.BuildWithRetry(context.Background(), http.DefaultClient, fastTestPolicy())
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"retry me", "retry me"}, bodies)
+}
+
+func TestBuildWithRetryGivesUpWhenBodyExceedsBufferCap(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	request := NewRequestBuilder(http.MethodGet).ConstructHTTPURL(server.URL, nil, nil)
+	request.Body = opaqueReader{strings.NewReader("this body is bigger than the tiny cap")}
+
+	policy := fastTestPolicy()
+	policy.MaxBufferedBodyBytes = 4
+
+	resp, err := request.BuildWithRetry(context.Background(), http.DefaultClient, policy)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestBuildWithRetryReplaysBufferedBody(t *testing.T) {
+	var attempts int
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		buf := make([]byte, r.ContentLength)
+		r.Body.Read(buf)
+		bodies = append(bodies, string(buf))
+		if attempts < 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request, err := NewRequestBuilder(http.MethodGet).
+		ConstructHTTPURL(server.URL, nil, nil).
+		SetBodyContentString("retry me")
+	assert.Nil(t, err)
+
+	resp, err := request.BuildWithRetry(context.Background(), http.DefaultClient, fastTestPolicy())
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"retry me", "retry me"}, bodies)
+}